@@ -0,0 +1,153 @@
+package vconfig
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// Codec marshals and unmarshals config documents in a particular file
+// format, and can peek at the top-level "version" field without decoding
+// the whole document.
+type Codec interface {
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+	PeekVersion(data []byte) (string, error)
+}
+
+var (
+	codecMu sync.RWMutex
+	codecs  = map[string]Codec{}
+)
+
+func init() {
+	RegisterCodec(".json", jsonCodec{})
+	RegisterCodec(".yaml", yamlCodec{})
+	RegisterCodec(".yml", yamlCodec{})
+	RegisterCodec(".toml", tomlCodec{})
+}
+
+// RegisterCodec associates a Codec with a file extension, including the
+// leading dot (e.g. ".yaml"). Registering an extension that is already
+// known replaces its Codec. Use this to plug in formats such as HCL or
+// CBOR.
+func RegisterCodec(ext string, c Codec) {
+	codecMu.Lock()
+	defer codecMu.Unlock()
+	codecs[strings.ToLower(ext)] = c
+}
+
+// codecFor returns the Codec registered for filename's extension.
+func codecFor(filename string) (Codec, error) {
+	ext := strings.ToLower(filepath.Ext(filename))
+
+	codecMu.RLock()
+	c, ok := codecs[ext]
+	codecMu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("vconfig: no codec registered for extension %q", ext)
+	}
+	return c, nil
+}
+
+// versionProbe is decoded instead of a caller's type when a codec only
+// needs to read the "version" field.
+type versionProbe struct {
+	Version string `json:"version" yaml:"version" toml:"version"`
+}
+
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v any) ([]byte, error) { return json.MarshalIndent(v, "", "  ") }
+
+func (jsonCodec) Unmarshal(data []byte, v any) error { return json.Unmarshal(data, v) }
+
+// PeekVersion scans the JSON token stream for the top-level "version" key
+// instead of unmarshaling the whole document.
+func (jsonCodec) PeekVersion(data []byte) (string, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+
+	tok, err := dec.Token()
+	if err != nil {
+		return "", err
+	}
+	if d, ok := tok.(json.Delim); !ok || d != '{' {
+		return "", fmt.Errorf("expected a JSON object")
+	}
+
+	for dec.More() {
+		keyTok, err := dec.Token()
+		if err != nil {
+			return "", err
+		}
+		key, _ := keyTok.(string)
+
+		if key == "version" {
+			var version string
+			if err := dec.Decode(&version); err != nil {
+				return "", fmt.Errorf("decode version: %w", err)
+			}
+			return version, nil
+		}
+
+		var skip json.RawMessage
+		if err := dec.Decode(&skip); err != nil {
+			return "", err
+		}
+	}
+
+	return "", fmt.Errorf(`no "version" field found`)
+}
+
+type yamlCodec struct{}
+
+func (yamlCodec) Marshal(v any) ([]byte, error) { return yaml.Marshal(v) }
+
+func (yamlCodec) Unmarshal(data []byte, v any) error { return yaml.Unmarshal(data, v) }
+
+// PeekVersion does a surface-level parse into versionProbe rather than the
+// caller's full type.
+func (yamlCodec) PeekVersion(data []byte) (string, error) {
+	var p versionProbe
+	if err := yaml.Unmarshal(data, &p); err != nil {
+		return "", err
+	}
+	if p.Version == "" {
+		return "", fmt.Errorf(`no "version" field found`)
+	}
+	return p.Version, nil
+}
+
+type tomlCodec struct{}
+
+func (tomlCodec) Marshal(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (tomlCodec) Unmarshal(data []byte, v any) error {
+	return toml.Unmarshal(data, v)
+}
+
+// PeekVersion does a surface-level parse into versionProbe rather than the
+// caller's full type.
+func (tomlCodec) PeekVersion(data []byte) (string, error) {
+	var p versionProbe
+	if err := toml.Unmarshal(data, &p); err != nil {
+		return "", err
+	}
+	if p.Version == "" {
+		return "", fmt.Errorf(`no "version" field found`)
+	}
+	return p.Version, nil
+}
@@ -0,0 +1,45 @@
+package vconfig
+
+import (
+	"fmt"
+)
+
+// CheckData validates that v is a struct with a non-empty "Version" field,
+// which vconfig requires on every config type so that GetVersion and the
+// migration pipeline have something to key off of.
+func CheckData(v any) error {
+	if isNilPointer(v) {
+		return fmt.Errorf("vconfig: CheckData: nil %T", v)
+	}
+	if !isStruct(v) {
+		return fmt.Errorf("vconfig: CheckData: %T is not a struct", v)
+	}
+
+	info := newStructInfo(v)
+
+	field, ok := info.FieldOk("Version")
+	if !ok {
+		return fmt.Errorf("vconfig: CheckData: %s has no Version field", info.Name())
+	}
+
+	version, ok := field.Interface().(string)
+	if !ok || version == "" {
+		return fmt.Errorf("vconfig: CheckData: %s has an empty Version field", info.Name())
+	}
+
+	return nil
+}
+
+// LoadConfig reads filename from DefaultStore and unmarshals it into a new
+// T, using the Codec registered for filename's extension. Use
+// LoadConfigFrom to read from a different Store.
+func LoadConfig[T any](filename string) (*T, error) {
+	return LoadConfigFrom[T](DefaultStore, filename)
+}
+
+// GetVersion reports the "version" field of the config stored at filename
+// in DefaultStore, without unmarshaling the whole document. Use
+// GetVersionFrom to read from a different Store.
+func GetVersion(filename string) (string, error) {
+	return GetVersionFrom(DefaultStore, filename)
+}
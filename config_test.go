@@ -0,0 +1,25 @@
+package vconfig
+
+import (
+	"testing"
+)
+
+type checkDataTestConfig struct {
+	Version string
+}
+
+func TestCheckDataNilPointerReturnsError(t *testing.T) {
+	var cfg *checkDataTestConfig
+
+	err := CheckData(cfg)
+	if err == nil {
+		t.Fatal("want an error for a nil *checkDataTestConfig, not a panic")
+	}
+}
+
+func TestCheckDataNonStructReturnsError(t *testing.T) {
+	err := CheckData("not a struct")
+	if err == nil {
+		t.Fatal("want an error for a non-struct value")
+	}
+}
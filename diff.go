@@ -0,0 +1,274 @@
+package vconfig
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+)
+
+// vconfigExcludeTag is the tag value that excludes a field from Diff and
+// DeepDiff output, e.g. `vconfig:"-"` on a password or token field.
+const vconfigExcludeTag = "-"
+
+// FieldChange describes a single field-level difference found by Diff or
+// DeepDiff.
+type FieldChange interface {
+	// Name returns the struct field name the change belongs to.
+	Name() string
+	// Path returns the dotted path to the changed value, including nested
+	// struct fields, map keys and slice indices (e.g. "Database.Hosts.0").
+	Path() string
+	// OldValue returns the value taken from the first argument passed to
+	// Diff or DeepDiff.
+	OldValue() any
+	// NewValue returns the value taken from the second argument passed to
+	// Diff or DeepDiff.
+	NewValue() any
+}
+
+type fieldChange struct {
+	name     string
+	path     string
+	oldValue any
+	newValue any
+}
+
+func (c *fieldChange) Name() string  { return c.name }
+func (c *fieldChange) Path() string  { return c.path }
+func (c *fieldChange) OldValue() any { return c.oldValue }
+func (c *fieldChange) NewValue() any { return c.newValue }
+
+// Diff compares the top-level fields of a and b by name and returns one
+// FieldChange per field whose value differs. a and b must be the same
+// struct type, unexported fields are ignored, and fields tagged
+// `vconfig:"-"` are excluded.
+func Diff(a, b any) ([]FieldChange, error) {
+	aInfo, bInfo, err := sameStructType(a, b, "Diff")
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []FieldChange
+	for _, name := range aInfo.FieldNames() {
+		af, _ := aInfo.FieldOk(name)
+		bf, _ := bInfo.FieldOk(name)
+
+		if af.Tag("vconfig") == vconfigExcludeTag {
+			continue
+		}
+
+		if !valuesEqual(af.value, bf.value) {
+			changes = append(changes, &fieldChange{
+				name:     name,
+				path:     name,
+				oldValue: af.Interface(),
+				newValue: bf.Interface(),
+			})
+		}
+	}
+
+	return changes, nil
+}
+
+// DeepDiff compares a and b field by field, recursing into nested structs,
+// maps and slices and reporting changes at their leaf path, using
+// reflect.DeepEqual semantics on leaf values. a and b must be the same
+// struct type, unexported fields are ignored, and fields (at any depth)
+// tagged `vconfig:"-"` are excluded. A zero value and a missing value
+// (e.g. a nil pointer vs. its pointee's zero value) are treated as equal.
+func DeepDiff(a, b any) ([]FieldChange, error) {
+	aInfo, bInfo, err := sameStructType(a, b, "DeepDiff")
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []FieldChange
+	for _, name := range aInfo.FieldNames() {
+		af, _ := aInfo.FieldOk(name)
+		bf, _ := bInfo.FieldOk(name)
+
+		if af.Tag("vconfig") == vconfigExcludeTag {
+			continue
+		}
+
+		changes = append(changes, diffValue(name, name, af.value, bf.value)...)
+	}
+
+	return changes, nil
+}
+
+func sameStructType(a, b any, caller string) (*structInfo, *structInfo, error) {
+	if !isStruct(a) || !isStruct(b) {
+		return nil, nil, fmt.Errorf("vconfig: %s: both arguments must be structs", caller)
+	}
+
+	aInfo := newStructInfo(a)
+	bInfo := newStructInfo(b)
+
+	if aInfo.typ != bInfo.typ {
+		return nil, nil, fmt.Errorf("vconfig: %s: arguments must be the same type, got %s and %s", caller, aInfo.typ, bInfo.typ)
+	}
+
+	return aInfo, bInfo, nil
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// diffValue recurses into a and b, reporting a FieldChange per leaf value
+// that differs between them.
+func diffValue(name, path string, a, b reflect.Value) []FieldChange {
+	a = indirectOrZero(a)
+	b = indirectOrZero(b)
+
+	typ := valueType(a, b)
+
+	switch {
+	case typ != nil && typ.Kind() == reflect.Struct && typ != timeType:
+		var changes []FieldChange
+		for i := 0; i < typ.NumField(); i++ {
+			field := typ.Field(i)
+			if field.PkgPath != "" {
+				continue
+			}
+			if field.Tag.Get("vconfig") == vconfigExcludeTag {
+				continue
+			}
+			changes = append(changes, diffValue(field.Name, path+"."+field.Name, fieldOrZero(a, i), fieldOrZero(b, i))...)
+		}
+		return changes
+
+	case typ != nil && (typ.Kind() == reflect.Slice || typ.Kind() == reflect.Array):
+		var changes []FieldChange
+		n := sliceLen(a)
+		if l := sliceLen(b); l > n {
+			n = l
+		}
+		for i := 0; i < n; i++ {
+			changes = append(changes, diffValue(name, fmt.Sprintf("%s.%d", path, i), sliceIndex(a, i), sliceIndex(b, i))...)
+		}
+		return changes
+
+	case typ != nil && typ.Kind() == reflect.Map:
+		var changes []FieldChange
+		for _, key := range mapKeys(a, b) {
+			changes = append(changes, diffValue(name, fmt.Sprintf("%s.%v", path, key.Interface()), mapIndex(a, key), mapIndex(b, key))...)
+		}
+		return changes
+
+	default:
+		if !valuesEqual(a, b) {
+			return []FieldChange{&fieldChange{
+				name:     name,
+				path:     path,
+				oldValue: interfaceOrNil(a),
+				newValue: interfaceOrNil(b),
+			}}
+		}
+		return nil
+	}
+}
+
+// valueType returns the type shared by a and b, falling back to whichever
+// one is valid when the other is a missing map entry or short slice index.
+func valueType(a, b reflect.Value) reflect.Type {
+	if a.IsValid() {
+		return a.Type()
+	}
+	if b.IsValid() {
+		return b.Type()
+	}
+	return nil
+}
+
+func indirectOrZero(v reflect.Value) reflect.Value {
+	if !v.IsValid() {
+		return v
+	}
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return reflect.Zero(v.Type().Elem())
+		}
+		return v.Elem()
+	}
+	return v
+}
+
+func fieldOrZero(v reflect.Value, i int) reflect.Value {
+	if !v.IsValid() {
+		return v
+	}
+	return v.Field(i)
+}
+
+func sliceLen(v reflect.Value) int {
+	if !v.IsValid() {
+		return 0
+	}
+	return v.Len()
+}
+
+func sliceIndex(v reflect.Value, i int) reflect.Value {
+	if !v.IsValid() || i >= v.Len() {
+		return reflect.Value{}
+	}
+	return v.Index(i)
+}
+
+// mapKeys returns the union of a's and b's keys, sorted by their formatted
+// representation so DeepDiff reports map entries in a stable order across
+// calls rather than following Go's randomized map iteration.
+func mapKeys(a, b reflect.Value) []reflect.Value {
+	seen := make(map[any]reflect.Value)
+	if a.IsValid() {
+		for _, k := range a.MapKeys() {
+			seen[k.Interface()] = k
+		}
+	}
+	if b.IsValid() {
+		for _, k := range b.MapKeys() {
+			seen[k.Interface()] = k
+		}
+	}
+
+	keys := make([]reflect.Value, 0, len(seen))
+	for _, k := range seen {
+		keys = append(keys, k)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		return fmt.Sprint(keys[i].Interface()) < fmt.Sprint(keys[j].Interface())
+	})
+
+	return keys
+}
+
+func mapIndex(v, key reflect.Value) reflect.Value {
+	if !v.IsValid() {
+		return reflect.Value{}
+	}
+	return v.MapIndex(key)
+}
+
+func interfaceOrNil(v reflect.Value) any {
+	if !v.IsValid() {
+		return nil
+	}
+	return v.Interface()
+}
+
+// valuesEqual reports whether a and b hold equal values, treating a missing
+// value (the zero reflect.Value from a short slice or absent map key) as
+// equal to the zero value of the other side's type.
+func valuesEqual(a, b reflect.Value) bool {
+	if !a.IsValid() && !b.IsValid() {
+		return true
+	}
+	if !a.IsValid() {
+		return b.IsZero()
+	}
+	if !b.IsValid() {
+		return a.IsZero()
+	}
+	return reflect.DeepEqual(a.Interface(), b.Interface())
+}
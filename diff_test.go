@@ -0,0 +1,96 @@
+package vconfig
+
+import (
+	"testing"
+)
+
+type diffConfig struct {
+	Version string
+	Name    string
+	Tags    map[string]string
+	Secret  string `vconfig:"-"`
+}
+
+func TestDiffExcludesTaggedField(t *testing.T) {
+	a := diffConfig{Version: "1", Name: "a", Secret: "old"}
+	b := diffConfig{Version: "1", Name: "b", Secret: "new"}
+
+	changes, err := Diff(a, b)
+	if err != nil {
+		t.Fatalf("Diff: %v", err)
+	}
+
+	if len(changes) != 1 || changes[0].Name() != "Name" {
+		t.Fatalf("want a single Name change, got %+v", changes)
+	}
+}
+
+func TestDeepDiffMapOrderDeterministic(t *testing.T) {
+	a := diffConfig{
+		Version: "1",
+		Tags:    map[string]string{"b": "1", "z": "1", "a": "1", "m": "1"},
+	}
+	b := diffConfig{
+		Version: "1",
+		Tags:    map[string]string{"b": "2", "z": "2", "a": "2", "m": "2"},
+	}
+
+	var want []string
+	for i := 0; i < 20; i++ {
+		changes, err := DeepDiff(a, b)
+		if err != nil {
+			t.Fatalf("DeepDiff: %v", err)
+		}
+
+		got := make([]string, len(changes))
+		for i, c := range changes {
+			got[i] = c.Path()
+		}
+
+		if want == nil {
+			want = got
+			continue
+		}
+
+		if len(got) != len(want) {
+			t.Fatalf("run %d: got %d changes, want %d", i, len(got), len(want))
+		}
+		for j := range got {
+			if got[j] != want[j] {
+				t.Fatalf("run %d: map key order changed: got %v, want %v", i, got, want)
+			}
+		}
+	}
+}
+
+func TestDiffNilPointerReturnsError(t *testing.T) {
+	var a *diffConfig
+	b := &diffConfig{Version: "1"}
+
+	if _, err := Diff(a, b); err == nil {
+		t.Fatal("want an error for a nil *diffConfig, not a panic")
+	}
+}
+
+func TestDeepDiffNilPointerReturnsError(t *testing.T) {
+	var a *diffConfig
+	b := &diffConfig{Version: "1"}
+
+	if _, err := DeepDiff(a, b); err == nil {
+		t.Fatal("want an error for a nil *diffConfig, not a panic")
+	}
+}
+
+func TestDeepDiffExcludesTaggedField(t *testing.T) {
+	a := diffConfig{Version: "1", Secret: "old"}
+	b := diffConfig{Version: "1", Secret: "new"}
+
+	changes, err := DeepDiff(a, b)
+	if err != nil {
+		t.Fatalf("DeepDiff: %v", err)
+	}
+
+	if len(changes) != 0 {
+		t.Fatalf("want no changes for a vconfig:\"-\" field, got %+v", changes)
+	}
+}
@@ -0,0 +1,96 @@
+package vconfig
+
+import (
+	"context"
+	"fmt"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+// EtcdStore is a Store backed by an etcd v3 cluster, where key is an etcd
+// key. Put uses a single transactional compare-and-swap on the key's
+// mod_revision so concurrent writers cannot clobber each other's changes.
+type EtcdStore struct {
+	client *clientv3.Client
+}
+
+// NewEtcdStore wraps an existing etcd client.
+func NewEtcdStore(client *clientv3.Client) *EtcdStore {
+	return &EtcdStore{client: client}
+}
+
+func (s *EtcdStore) Get(key string) ([]byte, error) {
+	resp, err := s.client.Get(context.Background(), key)
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Kvs) == 0 {
+		return nil, fmt.Errorf("vconfig: etcd key %q not found", key)
+	}
+	return resp.Kvs[0].Value, nil
+}
+
+func (s *EtcdStore) Stat(key string) (bool, error) {
+	resp, err := s.client.Get(context.Background(), key, clientv3.WithCountOnly())
+	if err != nil {
+		return false, err
+	}
+	return resp.Count > 0, nil
+}
+
+// Put writes data at key inside a transaction that only commits if the
+// key's mod_revision hasn't changed since it was last read here, so two
+// writers racing to update the same config can't silently clobber one
+// another; the loser gets an error back instead.
+func (s *EtcdStore) Put(key string, data []byte) error {
+	ctx := context.Background()
+
+	get, err := s.client.Get(ctx, key)
+	if err != nil {
+		return err
+	}
+
+	var modRevision int64
+	if len(get.Kvs) > 0 {
+		modRevision = get.Kvs[0].ModRevision
+	}
+
+	resp, err := s.client.Txn(ctx).
+		If(clientv3.Compare(clientv3.ModRevision(key), "=", modRevision)).
+		Then(clientv3.OpPut(key, string(data))).
+		Commit()
+	if err != nil {
+		return err
+	}
+	if !resp.Succeeded {
+		return fmt.Errorf("vconfig: etcd put %q: concurrent modification, retry", key)
+	}
+
+	return nil
+}
+
+// Watch fires an Event each time key changes in etcd. The returned
+// channel is closed once ctx is canceled or the underlying etcd watch
+// ends.
+func (s *EtcdStore) Watch(ctx context.Context, key string) <-chan Event {
+	out := make(chan Event)
+
+	go func() {
+		defer close(out)
+
+		for resp := range s.client.Watch(ctx, key) {
+			for _, ev := range resp.Events {
+				if ev.Kv == nil {
+					continue
+				}
+				select {
+				case out <- Event{Key: string(ev.Kv.Key), Data: ev.Kv.Value}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return out
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"fmt"
 	"log"
+	"os"
 
 	"github.com/zbiljic/vconfig-go"
 )
@@ -10,12 +11,12 @@ import (
 // AppConfig represents our application configuration
 // Note: Version field is required by vconfig
 type AppConfig struct {
-	Version      string
-	AppName      string
-	Debug        bool
-	DatabaseURL  string
-	MaxRetries   int
-	AllowedHosts []string
+	Version      string   `json:"version"`
+	AppName      string   `json:"app_name"`
+	Debug        bool     `json:"debug"`
+	DatabaseURL  string   `json:"database_url" vconfig:"env=APP_DATABASE_URL"`
+	MaxRetries   int      `json:"max_retries"`
+	AllowedHosts []string `json:"allowed_hosts"`
 }
 
 func main() {
@@ -68,6 +69,38 @@ func main() {
 	}
 	fmt.Println("Configuration is valid!")
 
+	// Demonstrate an environment-variable override via LoadConfigWithOptions
+	fmt.Println("\nLoading configuration with an environment override...")
+	os.Setenv("APP_DATABASE_URL", "postgres://prod-db/myapp")
+	overriddenConfig, err := vconfig.LoadConfigWithOptions[AppConfig]("app_config.json")
+	if err != nil {
+		log.Fatalf("Failed to load config with options: %v", err)
+	}
+	fmt.Printf("  Database URL: %s\n", overriddenConfig.DatabaseURL)
+
+	// Demonstrate Diff between the on-disk config and the overridden one
+	fmt.Println("\nDiffing loaded configuration against the override...")
+	changes, err := vconfig.Diff(loadedConfig, overriddenConfig)
+	if err != nil {
+		log.Fatalf("Failed to diff configs: %v", err)
+	}
+	for _, c := range changes {
+		fmt.Printf("  %s: %v -> %v\n", c.Path(), c.OldValue(), c.NewValue())
+	}
+
+	// Demonstrate reading and writing through an explicit Store, as an
+	// alternative to DefaultStore
+	fmt.Println("\nSaving configuration through vconfig.DefaultStore directly...")
+	if err := vconfig.SaveConfigTo(vconfig.DefaultStore, overriddenConfig, "app_config.prod.json"); err != nil {
+		log.Fatalf("Failed to save config through Store: %v", err)
+	}
+	storedConfig, err := vconfig.LoadConfigFrom[AppConfig](vconfig.DefaultStore, "app_config.prod.json")
+	if err != nil {
+		log.Fatalf("Failed to load config through Store: %v", err)
+	}
+	fmt.Printf("  Loaded back via Store: %s\n", storedConfig.DatabaseURL)
+	os.Remove("app_config.prod.json")
+
 	// Demonstrate what happens with invalid config (no Version field)
 	type InvalidConfig struct {
 		Name string
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
 	"log"
 	"os"
@@ -13,11 +14,8 @@ import (
 // Mutex for thread-safe access to config file
 var configMutex = &sync.RWMutex{}
 
-// Cached configs to avoid repeated file reads
-var (
-	cachedConfigV1 *ConfigV1
-	cachedConfigV2 *ConfigV2
-)
+// Cached config to avoid repeated file reads
+var cachedConfig *ConfigV2
 
 const (
 	configStateVersionV1 = "1"
@@ -42,6 +40,12 @@ type ConfigV2 struct {
 	Paths          []string  `json:"paths"`
 }
 
+func init() {
+	// Register the v1 -> v2 edge once; vconfig.Migrate walks it instead of
+	// a hand-rolled "if version == ... else if version == ..." chain.
+	vconfig.RegisterMigration(configStateVersionV1, configStateVersionV2, migrateV1ToV2)
+}
+
 func main() {
 	fmt.Println("State Config Migration Example (Real-World Pattern)")
 	fmt.Println("===================================================")
@@ -92,14 +96,25 @@ func configFilename(baseDir, stateName string) string {
 	return fmt.Sprintf("%s/.state-%s.json", baseDir, stateName)
 }
 
-// kept for completeness of the migration example
-//
-//nolint:unused
-func newConfigV1() *ConfigV1 {
-	config := new(ConfigV1)
-	config.Version = configStateVersionV1
-	config.Roots = make([]string, 0)
-	return config
+// migrateV1ToV2 is the vconfig.MigrationFunc registered for the "1" -> "2"
+// edge: it decodes the v1 document, carries over what still applies, and
+// re-encodes it as a v2 document.
+func migrateV1ToV2(raw json.RawMessage) (json.RawMessage, string, error) {
+	var v1 ConfigV1
+	if err := json.Unmarshal(raw, &v1); err != nil {
+		return nil, "", fmt.Errorf("unmarshal v1 config: %w", err)
+	}
+
+	v2 := newConfigV2()
+	v2.Roots = make([]string, len(v1.Roots))
+	copy(v2.Roots, v1.Roots)
+
+	migrated, err := json.Marshal(v2)
+	if err != nil {
+		return nil, "", fmt.Errorf("marshal v2 config: %w", err)
+	}
+
+	return migrated, configStateVersionV2, nil
 }
 
 // newConfigV2 creates a new v2 config
@@ -113,35 +128,14 @@ func newConfigV2() *ConfigV2 {
 	return config
 }
 
-// loadConfigV1 loads a v1 config with caching
-func loadConfigV1(baseDir, stateName string) (*ConfigV1, error) {
-	configMutex.RLock()
-	defer configMutex.RUnlock()
-
-	// Return cached if available
-	if cachedConfigV1 != nil {
-		return cachedConfigV1, nil
-	}
-
-	filename := configFilename(baseDir, stateName)
-	config, err := vconfig.LoadConfig[ConfigV1](filename)
-	if err != nil {
-		return nil, err
-	}
-
-	// Cache the config
-	cachedConfigV1 = config
-	return config, nil
-}
-
 // loadConfigV2 loads a v2 config with caching
 func loadConfigV2(baseDir, stateName string) (*ConfigV2, error) {
 	configMutex.RLock()
 	defer configMutex.RUnlock()
 
 	// Return cached if available
-	if cachedConfigV2 != nil {
-		return cachedConfigV2, nil
+	if cachedConfig != nil {
+		return cachedConfig, nil
 	}
 
 	filename := configFilename(baseDir, stateName)
@@ -151,7 +145,7 @@ func loadConfigV2(baseDir, stateName string) (*ConfigV2, error) {
 	}
 
 	// Cache the config
-	cachedConfigV2 = config
+	cachedConfig = config
 	return config, nil
 }
 
@@ -169,7 +163,7 @@ func saveConfigV2(baseDir, stateName string, config *ConfigV2) error {
 	}
 
 	// Update cache
-	cachedConfigV2 = config
+	cachedConfig = config
 	return nil
 }
 
@@ -180,7 +174,8 @@ func createConfig(roots ...string) (*ConfigV2, error) {
 	return config, nil
 }
 
-// loadCreateMigrateConfig loads existing config or creates new one, handling migrations
+// loadCreateMigrateConfig loads the existing config, migrating it to v2 if
+// it's on an older version, or creates a new v2 config if none exists yet.
 func loadCreateMigrateConfig(baseDir, stateName string, roots ...string) (*ConfigV2, error) {
 	filename := configFilename(baseDir, stateName)
 
@@ -201,38 +196,23 @@ func loadCreateMigrateConfig(baseDir, stateName string, roots ...string) (*Confi
 		return nil, err
 	}
 
-	// Handle different versions
-	switch version {
-	case configStateVersionV1:
-		fmt.Println("Found v1 config, migrating to v2...")
-		currentConfig, err := loadConfigV1(baseDir, stateName)
-		if err != nil {
-			return nil, fmt.Errorf("unable to load config version '%s': %w", version, err)
-		}
-
-		// Migrate v1 to v2
-		newConfig := newConfigV2()
-		newConfig.Roots = make([]string, len(currentConfig.Roots))
-		copy(newConfig.Roots, currentConfig.Roots)
+	if version == configStateVersionV2 {
+		fmt.Println("Found v2 config, loading...")
+		return loadConfigV2(baseDir, stateName)
+	}
 
-		if err := saveConfigV2(baseDir, stateName, newConfig); err != nil {
-			return nil, err
-		}
+	fmt.Printf("Found config version %s, migrating to v2...\n", version)
 
-		// Recursively call to load the migrated config
-		return loadCreateMigrateConfig(baseDir, stateName, roots...)
+	config, err := vconfig.Migrate[ConfigV2](filename, configStateVersionV2)
+	if err != nil {
+		return nil, fmt.Errorf("migrate config from version %q: %w", version, err)
+	}
 
-	case configStateVersionV2:
-		fmt.Println("Found v2 config, loading...")
-		currentConfig, err := loadConfigV2(baseDir, stateName)
-		if err != nil {
-			return nil, fmt.Errorf("unable to load config version '%s': %w", version, err)
-		}
-		return currentConfig, nil
+	configMutex.Lock()
+	cachedConfig = config
+	configMutex.Unlock()
 
-	default:
-		return nil, fmt.Errorf("unknown config version: '%s'", version)
-	}
+	return config, nil
 }
 
 // displayConfigV2 displays the v2 config details
@@ -252,9 +232,8 @@ func clearConfig(baseDir, stateName string) error {
 	configMutex.Lock()
 	defer configMutex.Unlock()
 
-	// Clear caches
-	cachedConfigV1 = nil
-	cachedConfigV2 = nil
+	// Clear cache
+	cachedConfig = nil
 
 	filename := configFilename(baseDir, stateName)
 	if _, err := os.Stat(filename); err != nil {
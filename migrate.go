@@ -0,0 +1,310 @@
+package vconfig
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+)
+
+// MigrationFunc transforms the raw bytes of a config document from one
+// version to the next, returning the transformed document along with the
+// version it now represents. raw is always JSON, even when the config's
+// on-disk format is YAML or TOML: runMigrationChain transcodes the
+// document to JSON before running the chain and back to the original
+// format afterward, so a MigrationFunc never has to know what codecFor
+// resolved for the file it came from.
+type MigrationFunc func(raw json.RawMessage) (json.RawMessage, string, error)
+
+// Migrator resolves a chain of registered version migrations and applies
+// them in sequence, similar in spirit to the hand-rolled "migrateV2ToV3,
+// migrateV3ToV4, ..." switch statements config loaders tend to grow.
+// Edges form a DAG rather than a strict line, so deprecated intermediate
+// versions can be skipped by registering a direct edge around them.
+type Migrator struct {
+	edges map[string]map[string]MigrationFunc // fromVersion -> toVersion -> fn
+}
+
+// NewMigrator creates an empty Migrator.
+func NewMigrator() *Migrator {
+	return &Migrator{edges: make(map[string]map[string]MigrationFunc)}
+}
+
+// DefaultMigrator is the Migrator used by the package-level RegisterMigration
+// and Migrate functions.
+var DefaultMigrator = NewMigrator()
+
+// RegisterMigration registers fn on the DefaultMigrator. See
+// (*Migrator).RegisterMigration.
+func RegisterMigration(fromVersion, toVersion string, fn MigrationFunc) {
+	DefaultMigrator.RegisterMigration(fromVersion, toVersion, fn)
+}
+
+// RegisterMigration registers fn as the edge that migrates a document from
+// fromVersion to toVersion. Registering more than one edge out of the same
+// fromVersion is allowed; Migrate picks among them to find a path to the
+// target version.
+func (m *Migrator) RegisterMigration(fromVersion, toVersion string, fn MigrationFunc) {
+	if m.edges[fromVersion] == nil {
+		m.edges[fromVersion] = make(map[string]MigrationFunc)
+	}
+	m.edges[fromVersion][toVersion] = fn
+}
+
+// path returns a sequence of versions, starting with from, that reaches to
+// by following registered edges. It explores the edge DAG breadth-first so
+// the shortest chain (e.g. one that skips deprecated intermediate versions)
+// is preferred.
+func (m *Migrator) path(from, to string) ([]string, error) {
+	if from == to {
+		return []string{from}, nil
+	}
+
+	type node struct {
+		version string
+		path    []string
+	}
+
+	visited := map[string]bool{from: true}
+	queue := []node{{version: from, path: []string{from}}}
+
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+
+		for next := range m.edges[cur.version] {
+			if visited[next] {
+				continue
+			}
+			visited[next] = true
+
+			nextPath := append(append([]string{}, cur.path...), next)
+			if next == to {
+				return nextPath, nil
+			}
+			queue = append(queue, node{version: next, path: nextPath})
+		}
+	}
+
+	return nil, fmt.Errorf("vconfig: no migration path from version %q to %q", from, to)
+}
+
+// MigrateOption configures a call to Migrate.
+type MigrateOption func(*migrateOptions)
+
+type migrateOptions struct {
+	dryRun bool
+	backup bool
+}
+
+// WithDryRun makes Migrate return the migrated value without overwriting
+// filename.
+func WithDryRun() MigrateOption {
+	return func(o *migrateOptions) { o.dryRun = true }
+}
+
+// WithMigrationBackup makes Migrate write the original, pre-migration
+// contents of filename to filename+".bak" before overwriting it.
+func WithMigrationBackup() MigrateOption {
+	return func(o *migrateOptions) { o.backup = true }
+}
+
+// Migrate reads filename, walks the DefaultMigrator's registered chain of
+// migrations from its on-disk version to targetVersion, and unmarshals the
+// result into a new T. Unless WithDryRun is given, the migrated document is
+// written back to filename.
+func Migrate[T any](filename, targetVersion string, opts ...MigrateOption) (*T, error) {
+	return MigrateWith[T](DefaultMigrator, filename, targetVersion, opts...)
+}
+
+// MigrateWith is like Migrate but walks m's registered chain instead of the
+// DefaultMigrator's, for callers that keep their migrations on a private
+// Migrator.
+func MigrateWith[T any](m *Migrator, filename, targetVersion string, opts ...MigrateOption) (*T, error) {
+	o := &migrateOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	original, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("vconfig: migrate %s: %w", filename, err)
+	}
+
+	out, raw, err := runMigrationChain[T](m, filename, original, targetVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	if o.dryRun {
+		return out, nil
+	}
+
+	if o.backup {
+		if err := backupFile(filename, defaultFileMode); err != nil {
+			return nil, fmt.Errorf("vconfig: migrate %s: write backup: %w", filename, err)
+		}
+	}
+
+	if err := writeFileAtomicFsync(filename, raw, defaultFileMode); err != nil {
+		return nil, fmt.Errorf("vconfig: migrate %s: %w", filename, err)
+	}
+
+	return out, nil
+}
+
+// MigrateFrom is like Migrate but reads key from and writes it back to
+// store instead of the local filesystem, so migrations can run against
+// remote-stored configs (e.g. in etcd) as well as local files.
+func MigrateFrom[T any](store Store, key, targetVersion string, opts ...MigrateOption) (*T, error) {
+	return MigrateFromWith[T](DefaultMigrator, store, key, targetVersion, opts...)
+}
+
+// MigrateFromWith is like MigrateFrom but walks m's registered chain
+// instead of the DefaultMigrator's.
+func MigrateFromWith[T any](m *Migrator, store Store, key, targetVersion string, opts ...MigrateOption) (*T, error) {
+	o := &migrateOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	original, err := store.Get(key)
+	if err != nil {
+		return nil, fmt.Errorf("vconfig: migrate %s: %w", key, err)
+	}
+
+	out, raw, err := runMigrationChain[T](m, key, original, targetVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	if o.dryRun {
+		return out, nil
+	}
+
+	if o.backup {
+		if err := store.Put(key+".bak", original); err != nil {
+			return nil, fmt.Errorf("vconfig: migrate %s: write backup: %w", key, err)
+		}
+	}
+
+	if err := store.Put(key, raw); err != nil {
+		return nil, fmt.Errorf("vconfig: migrate %s: %w", key, err)
+	}
+
+	return out, nil
+}
+
+// runMigrationChain walks m's registered edges from original's on-disk
+// version to targetVersion, applying each step in turn, and unmarshals the
+// final document into a new T. label is used both to find the Codec to
+// peek the version, transcode to and from JSON, and do the final unmarshal
+// with (via its extension), and to annotate log lines and errors (a
+// filename or a Store key). The returned json.RawMessage is in label's
+// native format, not JSON, ready to write back as-is.
+func runMigrationChain[T any](m *Migrator, label string, original json.RawMessage, targetVersion string) (*T, json.RawMessage, error) {
+	codec, err := codecFor(label)
+	if err != nil {
+		return nil, nil, fmt.Errorf("vconfig: migrate %s: %w", label, err)
+	}
+
+	version, err := codec.PeekVersion(original)
+	if err != nil {
+		return nil, nil, fmt.Errorf("vconfig: migrate %s: %w", label, err)
+	}
+
+	steps, err := m.path(version, targetVersion)
+	if err != nil {
+		return nil, nil, fmt.Errorf("vconfig: migrate %s: %w", label, err)
+	}
+
+	// Already at targetVersion: skip the JSON round trip entirely so a
+	// no-op Migrate call (e.g. one made on every startup) doesn't rewrite
+	// a YAML or TOML document's comments and key order away for nothing.
+	if len(steps) == 1 {
+		var out T
+		if err := codec.Unmarshal(original, &out); err != nil {
+			return nil, nil, fmt.Errorf("vconfig: migrate %s: unmarshal version %s: %w", label, targetVersion, err)
+		}
+		return &out, original, nil
+	}
+
+	raw, err := toJSON(codec, original)
+	if err != nil {
+		return nil, nil, fmt.Errorf("vconfig: migrate %s: %w", label, err)
+	}
+
+	for i := 0; i < len(steps)-1; i++ {
+		from, to := steps[i], steps[i+1]
+
+		fn, ok := m.edges[from][to]
+		if !ok {
+			return nil, nil, fmt.Errorf("vconfig: migrate %s: no migration registered from %q to %q", label, from, to)
+		}
+
+		migrated, gotVersion, err := fn(raw)
+		if err != nil {
+			return nil, nil, fmt.Errorf("vconfig: migrate %s: %s -> %s: %w", label, from, to, err)
+		}
+		if gotVersion != to {
+			return nil, nil, fmt.Errorf("vconfig: migrate %s: migration %s -> %s produced version %q", label, from, to, gotVersion)
+		}
+
+		log.Printf("vconfig: migrated %s from version %s to %s", label, from, to)
+		raw = migrated
+	}
+
+	native, err := fromJSON(codec, raw)
+	if err != nil {
+		return nil, nil, fmt.Errorf("vconfig: migrate %s: %w", label, err)
+	}
+
+	var out T
+	if err := codec.Unmarshal(native, &out); err != nil {
+		return nil, nil, fmt.Errorf("vconfig: migrate %s: unmarshal version %s: %w", label, targetVersion, err)
+	}
+
+	return &out, native, nil
+}
+
+// toJSON transcodes data, in codec's native format, to JSON, so a
+// MigrationFunc chain always sees JSON regardless of the document's
+// on-disk format. JSON documents pass through unchanged.
+func toJSON(codec Codec, data []byte) (json.RawMessage, error) {
+	if _, ok := codec.(jsonCodec); ok {
+		return json.RawMessage(data), nil
+	}
+
+	var v map[string]any
+	if err := codec.Unmarshal(data, &v); err != nil {
+		return nil, fmt.Errorf("transcode to JSON: %w", err)
+	}
+
+	out, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("transcode to JSON: %w", err)
+	}
+	return out, nil
+}
+
+// fromJSON transcodes raw, a JSON document, back to codec's native format,
+// the inverse of toJSON. Fields a MigrationFunc didn't touch still pass
+// through encoding/json's map[string]any, which decodes every JSON number
+// as float64; an integer field beyond float64's 2^53 precision will come
+// out rounded in the rewritten document.
+func fromJSON(codec Codec, raw json.RawMessage) ([]byte, error) {
+	if _, ok := codec.(jsonCodec); ok {
+		return raw, nil
+	}
+
+	var v map[string]any
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return nil, fmt.Errorf("transcode from JSON: %w", err)
+	}
+
+	out, err := codec.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("transcode from JSON: %w", err)
+	}
+	return out, nil
+}
@@ -0,0 +1,227 @@
+package vconfig
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type migrateTestConfig struct {
+	Version string `json:"version"`
+}
+
+func TestMigratorPathPrefersDirectEdgeOverIntermediate(t *testing.T) {
+	m := NewMigrator()
+
+	var viaDirect, viaIntermediate bool
+
+	m.RegisterMigration("1", "2", func(raw json.RawMessage) (json.RawMessage, string, error) {
+		viaIntermediate = true
+		return []byte(`{"version":"2"}`), "2", nil
+	})
+	m.RegisterMigration("2", "3", func(raw json.RawMessage) (json.RawMessage, string, error) {
+		return []byte(`{"version":"3"}`), "3", nil
+	})
+	m.RegisterMigration("1", "3", func(raw json.RawMessage) (json.RawMessage, string, error) {
+		viaDirect = true
+		return []byte(`{"version":"3"}`), "3", nil
+	})
+
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "state.json")
+	if err := os.WriteFile(filename, []byte(`{"version":"1"}`), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	got, err := MigrateWith[migrateTestConfig](m, filename, "3")
+	if err != nil {
+		t.Fatalf("MigrateWith: %v", err)
+	}
+	if got.Version != "3" {
+		t.Fatalf("got version %q, want %q", got.Version, "3")
+	}
+	if !viaDirect || viaIntermediate {
+		t.Fatalf("want the direct 1->3 edge preferred over the 1->2->3 chain, got viaDirect=%v viaIntermediate=%v", viaDirect, viaIntermediate)
+	}
+}
+
+func TestMigratorPathFallsBackToIntermediateWhenNoDirectEdge(t *testing.T) {
+	m := NewMigrator()
+
+	m.RegisterMigration("1", "2", func(raw json.RawMessage) (json.RawMessage, string, error) {
+		return []byte(`{"version":"2"}`), "2", nil
+	})
+	m.RegisterMigration("2", "3", func(raw json.RawMessage) (json.RawMessage, string, error) {
+		return []byte(`{"version":"3"}`), "3", nil
+	})
+
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "state.json")
+	if err := os.WriteFile(filename, []byte(`{"version":"1"}`), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	got, err := MigrateWith[migrateTestConfig](m, filename, "3")
+	if err != nil {
+		t.Fatalf("MigrateWith: %v", err)
+	}
+	if got.Version != "3" {
+		t.Fatalf("got version %q, want %q", got.Version, "3")
+	}
+}
+
+func TestMigrateWithDryRunLeavesFileUntouched(t *testing.T) {
+	m := NewMigrator()
+	m.RegisterMigration("1", "2", func(raw json.RawMessage) (json.RawMessage, string, error) {
+		return []byte(`{"version":"2"}`), "2", nil
+	})
+
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "state.json")
+	original := []byte(`{"version":"1"}`)
+	if err := os.WriteFile(filename, original, 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	got, err := MigrateWith[migrateTestConfig](m, filename, "2", WithDryRun())
+	if err != nil {
+		t.Fatalf("MigrateWith: %v", err)
+	}
+	if got.Version != "2" {
+		t.Fatalf("got version %q, want %q", got.Version, "2")
+	}
+
+	onDisk, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if string(onDisk) != string(original) {
+		t.Fatalf("WithDryRun wrote back to %s: got %q, want the original %q left in place", filename, onDisk, original)
+	}
+}
+
+func TestMigrateWithBackupKeepsOriginalContents(t *testing.T) {
+	m := NewMigrator()
+	m.RegisterMigration("1", "2", func(raw json.RawMessage) (json.RawMessage, string, error) {
+		return []byte(`{"version":"2"}`), "2", nil
+	})
+
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "state.json")
+	original := []byte(`{"version":"1"}`)
+	if err := os.WriteFile(filename, original, 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	if _, err := MigrateWith[migrateTestConfig](m, filename, "2", WithMigrationBackup()); err != nil {
+		t.Fatalf("MigrateWith: %v", err)
+	}
+
+	backup, err := os.ReadFile(filename + ".bak")
+	if err != nil {
+		t.Fatalf("ReadFile backup: %v", err)
+	}
+	if string(backup) != string(original) {
+		t.Fatalf("backup has %q, want the pre-migration contents %q", backup, original)
+	}
+
+	migrated, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	var cfg migrateTestConfig
+	if err := json.Unmarshal(migrated, &cfg); err != nil {
+		t.Fatalf("Unmarshal migrated file: %v", err)
+	}
+	if cfg.Version != "2" {
+		t.Fatalf("got migrated version %q, want %q", cfg.Version, "2")
+	}
+}
+
+func TestMigrateWithYAMLFileRunsMigrationFuncInJSON(t *testing.T) {
+	type migrateTestConfigV2 struct {
+		Version string `json:"version" yaml:"version"`
+		Name    string `json:"name" yaml:"name"`
+	}
+
+	m := NewMigrator()
+	m.RegisterMigration("1", "2", func(raw json.RawMessage) (json.RawMessage, string, error) {
+		var v1 migrateTestConfig
+		if err := json.Unmarshal(raw, &v1); err != nil {
+			return nil, "", err
+		}
+
+		v2 := migrateTestConfigV2{Version: "2", Name: "migrated"}
+		migrated, err := json.Marshal(v2)
+		if err != nil {
+			return nil, "", err
+		}
+		return migrated, "2", nil
+	})
+
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "state.yaml")
+	if err := os.WriteFile(filename, []byte("version: \"1\"\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	got, err := MigrateWith[migrateTestConfigV2](m, filename, "2")
+	if err != nil {
+		t.Fatalf("MigrateWith: %v", err)
+	}
+	if got.Version != "2" || got.Name != "migrated" {
+		t.Fatalf("got %+v, want version 2 with name %q", got, "migrated")
+	}
+
+	onDisk, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+
+	var cfg migrateTestConfigV2
+	if err := (yamlCodec{}).Unmarshal(onDisk, &cfg); err != nil {
+		t.Fatalf("migrated file is not valid YAML: %v\ncontent:\n%s", err, onDisk)
+	}
+	if cfg.Version != "2" || cfg.Name != "migrated" {
+		t.Fatalf("got %+v on disk, want version 2 with name %q", cfg, "migrated")
+	}
+}
+
+func TestMigrateWithYAMLFileAlreadyAtTargetVersionLeavesFileUntouched(t *testing.T) {
+	m := NewMigrator()
+	m.RegisterMigration("1", "2", func(raw json.RawMessage) (json.RawMessage, string, error) {
+		t.Fatal("migration ran even though the document is already at targetVersion")
+		return nil, "", nil
+	})
+
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "state.yaml")
+	original := []byte("# a comment\nversion: \"2\"\nzeta: 1\nalpha: 2\n")
+	if err := os.WriteFile(filename, original, 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	if _, err := MigrateWith[migrateTestConfig](m, filename, "2"); err != nil {
+		t.Fatalf("MigrateWith: %v", err)
+	}
+
+	onDisk, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if string(onDisk) != string(original) {
+		t.Fatalf("got %q, want the original %q left untouched", onDisk, original)
+	}
+}
+
+func TestMigratorPathNoRouteReturnsError(t *testing.T) {
+	m := NewMigrator()
+	m.RegisterMigration("1", "2", func(raw json.RawMessage) (json.RawMessage, string, error) {
+		return []byte(`{"version":"2"}`), "2", nil
+	})
+
+	if _, err := m.path("1", "99"); err == nil {
+		t.Fatal("want an error when no registered edge reaches the target version")
+	}
+}
@@ -0,0 +1,353 @@
+package vconfig
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Option configures LoadConfigWithOptions.
+type Option func(*loadOptions)
+
+type loadOptions struct {
+	env        string
+	envPrefix  string
+	requireEnv bool
+}
+
+// WithEnv sets the environment name used to locate a sibling overlay file,
+// filename.<env>.<ext>. If not given, the VCONFIG_ENV environment variable
+// is used instead.
+func WithEnv(env string) Option {
+	return func(o *loadOptions) { o.env = env }
+}
+
+// WithEnvPrefix enables environment-variable overlays for untagged fields,
+// matching prefix + "_" + the field name in SCREAMING_SNAKE_CASE (e.g.
+// prefix "APP" matches APP_DATABASE_URL against a DatabaseURL field).
+// Fields tagged `vconfig:"env=NAME"` are always matched by their exact
+// name, with or without a prefix.
+func WithEnvPrefix(prefix string) Option {
+	return func(o *loadOptions) { o.envPrefix = prefix }
+}
+
+// WithRequiredEnv fails LoadConfigWithOptions if a field tagged
+// `vconfig:"env=NAME"` has no corresponding environment variable set.
+func WithRequiredEnv() Option {
+	return func(o *loadOptions) { o.requireEnv = true }
+}
+
+// LoadConfigWithOptions loads filename like LoadConfig, then applies two
+// overlays in order: a sibling environment file (see WithEnv) deep-merged
+// into the base struct, and environment variables (see WithEnvPrefix)
+// applied on top of that. Neither overlay may change the Version field.
+// CheckData is run once on the final, effective config. Use
+// LoadConfigWithOptionsFrom to read both the base config and the env
+// overlay from a Store other than DefaultStore.
+func LoadConfigWithOptions[T any](filename string, opts ...Option) (*T, error) {
+	return LoadConfigWithOptionsFrom[T](DefaultStore, filename, opts...)
+}
+
+// LoadConfigWithOptionsFrom is like LoadConfigWithOptions but reads the base
+// config and, if present, the env overlay from store instead of the local
+// filesystem, so the overlay behaves the same whether DefaultStore is a
+// LocalStore or a remote Store such as EtcdStore.
+func LoadConfigWithOptionsFrom[T any](store Store, key string, opts ...Option) (*T, error) {
+	o := &loadOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.env == "" {
+		o.env = os.Getenv("VCONFIG_ENV")
+	}
+
+	cfg, err := LoadConfigFrom[T](store, key)
+	if err != nil {
+		return nil, err
+	}
+	version := getVersionField(cfg)
+
+	if o.env != "" {
+		overlayKey := envOverlayFilename(key, o.env)
+
+		exists, err := store.Stat(overlayKey)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			if err := mergeOverlayFrom(store, overlayKey, cfg); err != nil {
+				return nil, fmt.Errorf("vconfig: LoadConfigWithOptionsFrom: env overlay %s: %w", overlayKey, err)
+			}
+		}
+	}
+
+	if err := applyEnvOverlay(cfg, o.envPrefix, o.requireEnv); err != nil {
+		return nil, fmt.Errorf("vconfig: LoadConfigWithOptionsFrom: %w", err)
+	}
+
+	setVersionField(cfg, version)
+
+	if err := CheckData(cfg); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// envOverlayFilename returns the sibling overlay filename for env, e.g.
+// "app.yaml" with env "production" becomes "app.production.yaml".
+func envOverlayFilename(filename, env string) string {
+	ext := filepath.Ext(filename)
+	base := strings.TrimSuffix(filename, ext)
+	return fmt.Sprintf("%s.%s%s", base, env, ext)
+}
+
+// mergeOverlayFrom reads overlayKey from store and merges only the fields
+// it explicitly sets into cfg. It decodes overlayKey twice: once into a
+// fresh T so the values have the right Go types, and once into a generic
+// map so mergeStruct can tell a key the overlay set to its zero value
+// (e.g. {"debug": false}) apart from a key the overlay omitted entirely.
+func mergeOverlayFrom[T any](store Store, overlayKey string, cfg *T) error {
+	codec, err := codecFor(overlayKey)
+	if err != nil {
+		return err
+	}
+
+	data, err := store.Get(overlayKey)
+	if err != nil {
+		return err
+	}
+
+	var overlay T
+	if err := codec.Unmarshal(data, &overlay); err != nil {
+		return err
+	}
+
+	var present map[string]any
+	if err := codec.Unmarshal(data, &present); err != nil {
+		return err
+	}
+
+	return mergeStruct(cfg, &overlay, present)
+}
+
+// mergeStruct copies every field of src that present names into the
+// matching field of dst, recursing into nested structs, without touching
+// Version. present holds the overlay document decoded generically (as
+// produced by a Codec unmarshaling into a map[string]any), so a field is
+// copied only if the overlay actually set it, not merely because its
+// decoded value happens to be non-zero.
+func mergeStruct(dst, src any, present map[string]any) error {
+	dv := reflect.ValueOf(dst)
+	sv := reflect.ValueOf(src)
+	if dv.Kind() == reflect.Ptr {
+		dv = dv.Elem()
+	}
+	if sv.Kind() == reflect.Ptr {
+		sv = sv.Elem()
+	}
+	if dv.Type() != sv.Type() {
+		return fmt.Errorf("mergeStruct: type mismatch: %s and %s", dv.Type(), sv.Type())
+	}
+
+	t := dv.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" || field.Name == "Version" {
+			continue
+		}
+
+		df, sf := dv.Field(i), sv.Field(i)
+		value, ok := lookupPresent(present, field)
+
+		if field.Type.Kind() == reflect.Struct && field.Type != timeType {
+			nested, _ := value.(map[string]any)
+			if err := mergeStruct(df.Addr().Interface(), sf.Addr().Interface(), nested); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if ok {
+			df.Set(sf)
+		}
+	}
+
+	return nil
+}
+
+// lookupPresent reports whether present, a document decoded generically by
+// a Codec, has a key addressing field, trying its json/yaml/toml tag names
+// before falling back to the bare field name.
+func lookupPresent(present map[string]any, field reflect.StructField) (any, bool) {
+	for _, name := range keyCandidates(field) {
+		for key, value := range present {
+			if strings.EqualFold(key, name) {
+				return value, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// keyCandidates returns the key names a Codec could use to address field in
+// an encoded document, preferring an explicit json/yaml/toml tag over the
+// bare field name.
+func keyCandidates(field reflect.StructField) []string {
+	var candidates []string
+	for _, tagKey := range []string{"json", "yaml", "toml"} {
+		name := strings.Split(field.Tag.Get(tagKey), ",")[0]
+		if name != "" && name != "-" {
+			candidates = append(candidates, name)
+		}
+	}
+	return append(candidates, field.Name)
+}
+
+// applyEnvOverlay walks cfg's fields, setting each one whose matching
+// environment variable is present. See WithEnvPrefix for the matching
+// rules.
+func applyEnvOverlay(cfg any, prefix string, required bool) error {
+	rv := reflect.ValueOf(cfg)
+	if rv.Kind() == reflect.Ptr {
+		rv = rv.Elem()
+	}
+	return applyEnvOverlayValue(rv, prefix, required)
+}
+
+func applyEnvOverlayValue(v reflect.Value, prefix string, required bool) error {
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" || field.Name == "Version" {
+			continue
+		}
+
+		fv := v.Field(i)
+
+		envName, explicit := parseEnvTag(field.Tag.Get("vconfig"))
+		if !explicit && prefix != "" {
+			envName = prefix + "_" + toScreamingSnakeCase(field.Name)
+		}
+
+		if field.Type.Kind() == reflect.Struct && field.Type != timeType {
+			nestedPrefix := prefix
+			if nestedPrefix != "" {
+				nestedPrefix += "_" + toScreamingSnakeCase(field.Name)
+			}
+			if err := applyEnvOverlayValue(fv, nestedPrefix, required); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if envName == "" {
+			continue
+		}
+
+		raw, ok := os.LookupEnv(envName)
+		if !ok {
+			if explicit && required {
+				return fmt.Errorf("required environment variable %q is not set", envName)
+			}
+			continue
+		}
+
+		if err := setFieldFromString(fv, raw); err != nil {
+			return fmt.Errorf("environment variable %q: %w", envName, err)
+		}
+	}
+
+	return nil
+}
+
+// parseEnvTag parses a `vconfig:"env=NAME"` tag value.
+func parseEnvTag(tag string) (name string, explicit bool) {
+	const envPrefix = "env="
+	if strings.HasPrefix(tag, envPrefix) {
+		return strings.TrimPrefix(tag, envPrefix), true
+	}
+	return "", false
+}
+
+// toScreamingSnakeCase converts a Go field name such as "DatabaseURL" to
+// "DATABASE_URL".
+func toScreamingSnakeCase(s string) string {
+	var b strings.Builder
+	for i, r := range s {
+		if i > 0 && unicode.IsUpper(r) && !unicode.IsUpper(rune(s[i-1])) {
+			b.WriteByte('_')
+		}
+		b.WriteRune(unicode.ToUpper(r))
+	}
+	return b.String()
+}
+
+func setFieldFromString(fv reflect.Value, raw string) error {
+	if !fv.CanSet() {
+		return nil
+	}
+
+	switch fv.Kind() {
+	case reflect.String:
+		fv.SetString(raw)
+
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		fv.SetBool(b)
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return err
+		}
+		fv.SetInt(n)
+
+	case reflect.Slice:
+		if fv.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("unsupported slice element type %s", fv.Type().Elem())
+		}
+		parts := strings.Split(raw, ",")
+		for i, p := range parts {
+			parts[i] = strings.TrimSpace(p)
+		}
+		fv.Set(reflect.ValueOf(parts))
+
+	default:
+		return fmt.Errorf("unsupported field type %s", fv.Type())
+	}
+
+	return nil
+}
+
+func getVersionField(v any) string {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Ptr {
+		rv = rv.Elem()
+	}
+	f := rv.FieldByName("Version")
+	if !f.IsValid() {
+		return ""
+	}
+	s, _ := f.Interface().(string)
+	return s
+}
+
+func setVersionField(v any, version string) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Ptr {
+		rv = rv.Elem()
+	}
+	f := rv.FieldByName("Version")
+	if f.IsValid() && f.CanSet() && f.Kind() == reflect.String {
+		f.SetString(version)
+	}
+}
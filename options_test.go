@@ -0,0 +1,172 @@
+package vconfig
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type optionsTestConfig struct {
+	Version     string `json:"version"`
+	Debug       bool   `json:"debug"`
+	Name        string `json:"name"`
+	DatabaseURL string `json:"database_url" vconfig:"env=APP_DATABASE_URL"`
+	MaxRetries  int    `json:"max_retries"`
+	Ports       []int  `json:"ports"`
+}
+
+func TestLoadConfigWithOptionsEnvOverlayOverridesToZeroValue(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "app.json")
+
+	if err := SaveConfig(&optionsTestConfig{Version: "1", Debug: true, Name: "base"}, filename); err != nil {
+		t.Fatalf("SaveConfig: %v", err)
+	}
+	// Hand-written so it omits "name" entirely, rather than round-tripping
+	// through SaveConfig which would marshal it as present with "".
+	overlay := []byte(`{"version":"1","debug":false}`)
+	if err := os.WriteFile(filepath.Join(dir, "app.prod.json"), overlay, 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	got, err := LoadConfigWithOptions[optionsTestConfig](filename, WithEnv("prod"))
+	if err != nil {
+		t.Fatalf("LoadConfigWithOptions: %v", err)
+	}
+
+	if got.Debug {
+		t.Fatalf("got Debug true, want the prod overlay's false to win")
+	}
+	if got.Name != "base" {
+		t.Fatalf("got Name %q, want the base value %q to survive since the overlay omitted it", got.Name, "base")
+	}
+}
+
+func TestLoadConfigWithOptionsFromRoutesOverlayThroughStore(t *testing.T) {
+	fake := newFakeStore()
+
+	if err := SaveConfigTo(fake, &optionsTestConfig{Version: "1", Debug: true, Name: "base"}, "app.json"); err != nil {
+		t.Fatalf("SaveConfigTo: %v", err)
+	}
+	// Hand-written so it omits "name" entirely, exercising the same
+	// presence-aware merge as the local-filesystem case.
+	if err := fake.Put("app.prod.json", []byte(`{"version":"1","debug":false}`)); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+
+	got, err := LoadConfigWithOptionsFrom[optionsTestConfig](fake, "app.json", WithEnv("prod"))
+	if err != nil {
+		t.Fatalf("LoadConfigWithOptionsFrom: %v", err)
+	}
+
+	if got.Debug {
+		t.Fatalf("got Debug true, want the prod overlay's false to win")
+	}
+	if got.Name != "base" {
+		t.Fatalf("got Name %q, want the base value %q to survive since the overlay omitted it", got.Name, "base")
+	}
+}
+
+func TestLoadConfigWithOptionsNoOverlayFile(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "app.json")
+
+	if err := SaveConfig(&optionsTestConfig{Version: "1", Name: "base"}, filename); err != nil {
+		t.Fatalf("SaveConfig: %v", err)
+	}
+
+	got, err := LoadConfigWithOptions[optionsTestConfig](filename, WithEnv("missing"))
+	if err != nil {
+		t.Fatalf("LoadConfigWithOptions: %v", err)
+	}
+	if got.Name != "base" {
+		t.Fatalf("got Name %q, want %q", got.Name, "base")
+	}
+}
+
+func TestLoadConfigWithOptionsEnvPrefixMatching(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "app.json")
+
+	if err := SaveConfig(&optionsTestConfig{Version: "1", MaxRetries: 3}, filename); err != nil {
+		t.Fatalf("SaveConfig: %v", err)
+	}
+
+	os.Setenv("APP_MAX_RETRIES", "5")
+	defer os.Unsetenv("APP_MAX_RETRIES")
+
+	got, err := LoadConfigWithOptions[optionsTestConfig](filename, WithEnvPrefix("APP"))
+	if err != nil {
+		t.Fatalf("LoadConfigWithOptions: %v", err)
+	}
+	if got.MaxRetries != 5 {
+		t.Fatalf("got MaxRetries %d, want %d", got.MaxRetries, 5)
+	}
+}
+
+func TestLoadConfigWithOptionsExplicitEnvTagIgnoresPrefix(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "app.json")
+
+	if err := SaveConfig(&optionsTestConfig{Version: "1", DatabaseURL: "postgres://localhost/app"}, filename); err != nil {
+		t.Fatalf("SaveConfig: %v", err)
+	}
+
+	os.Setenv("APP_DATABASE_URL", "postgres://prod-db/app")
+	defer os.Unsetenv("APP_DATABASE_URL")
+
+	got, err := LoadConfigWithOptions[optionsTestConfig](filename)
+	if err != nil {
+		t.Fatalf("LoadConfigWithOptions: %v", err)
+	}
+	if got.DatabaseURL != "postgres://prod-db/app" {
+		t.Fatalf("got DatabaseURL %q, want the explicit vconfig env tag to match without WithEnvPrefix", got.DatabaseURL)
+	}
+}
+
+func TestLoadConfigWithOptionsRequiredEnvMissing(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "app.json")
+
+	if err := SaveConfig(&optionsTestConfig{Version: "1"}, filename); err != nil {
+		t.Fatalf("SaveConfig: %v", err)
+	}
+
+	os.Unsetenv("APP_DATABASE_URL")
+
+	_, err := LoadConfigWithOptions[optionsTestConfig](filename, WithRequiredEnv())
+	if err == nil {
+		t.Fatal("want an error when a required env-tagged field has no environment variable set")
+	}
+}
+
+func TestLoadConfigWithOptionsTypeCoercionErrors(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "app.json")
+
+	if err := SaveConfig(&optionsTestConfig{Version: "1"}, filename); err != nil {
+		t.Fatalf("SaveConfig: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		envVar string
+		value  string
+	}{
+		{name: "bool", envVar: "APP_DEBUG", value: "not-a-bool"},
+		{name: "int", envVar: "APP_MAX_RETRIES", value: "not-an-int"},
+		{name: "slice", envVar: "APP_PORTS", value: "80,443"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Setenv(tt.envVar, tt.value)
+			defer os.Unsetenv(tt.envVar)
+
+			_, err := LoadConfigWithOptions[optionsTestConfig](filename, WithEnvPrefix("APP"))
+			if err == nil {
+				t.Fatalf("want an error coercing %q into %s", tt.value, tt.name)
+			}
+		})
+	}
+}
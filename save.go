@@ -0,0 +1,203 @@
+package vconfig
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/gofrs/flock"
+)
+
+const defaultFileMode = 0o644
+
+// SaveOption configures SaveConfig.
+type SaveOption func(*saveOptions)
+
+type saveOptions struct {
+	backup   bool
+	fileMode os.FileMode
+	lock     bool
+}
+
+// WithBackup keeps a copy of filename's previous contents at
+// filename+".bak" before SaveConfig overwrites it, through the same Store
+// the write itself goes through.
+func WithBackup() SaveOption {
+	return func(o *saveOptions) { o.backup = true }
+}
+
+// WithFileMode sets the permissions SaveConfig writes filename with. The
+// default is 0644. Only honored when DefaultStore is a *LocalStore; other
+// Stores manage their own storage format and ignore it.
+func WithFileMode(mode os.FileMode) SaveOption {
+	return func(o *saveOptions) { o.fileMode = mode }
+}
+
+// WithLock takes a cross-process flock on a filename+".lock" sidecar file
+// for the duration of the write, so a checkpoint loop is safe even when
+// more than one process writes the same state file. It requires
+// DefaultStore to be a *LocalStore, since the lock is a local-filesystem
+// primitive with no equivalent for a remote Store such as EtcdStore (whose
+// Put is already safe for concurrent writers via a compare-and-swap).
+func WithLock() SaveOption {
+	return func(o *saveOptions) { o.lock = true }
+}
+
+// SaveConfig validates cfg with CheckData, then marshals it and writes it
+// to filename through DefaultStore (see SaveConfigTo to use a different
+// Store). When DefaultStore is the default LocalStore, the write goes
+// through the safe-write pattern: write a temp file in filename's
+// directory, fsync it, os.Rename it over filename, then fsync the parent
+// directory. The rename is atomic only when the temp file and filename
+// are on the same filesystem; SaveConfig surfaces the rename error rather
+// than falling back to a non-atomic copy across devices.
+func SaveConfig(cfg any, filename string, opts ...SaveOption) error {
+	if err := CheckData(cfg); err != nil {
+		return err
+	}
+
+	o := &saveOptions{fileMode: defaultFileMode}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	codec, err := codecFor(filename)
+	if err != nil {
+		return fmt.Errorf("vconfig: SaveConfig: %w", err)
+	}
+
+	data, err := codec.Marshal(cfg)
+	if err != nil {
+		return fmt.Errorf("vconfig: SaveConfig: %w", err)
+	}
+
+	store := DefaultStore
+	ls, isLocal := store.(*LocalStore)
+
+	if o.lock {
+		if !isLocal {
+			return fmt.Errorf("vconfig: SaveConfig: WithLock requires DefaultStore to be a *LocalStore, got %T", store)
+		}
+		fl := flock.New(filename + ".lock")
+		if err := fl.Lock(); err != nil {
+			return fmt.Errorf("vconfig: SaveConfig: lock: %w", err)
+		}
+		defer fl.Unlock()
+	}
+
+	if isLocal {
+		store = ls.withFileMode(o.fileMode)
+	}
+
+	if o.backup {
+		if err := backupThroughStore(store, filename); err != nil {
+			return fmt.Errorf("vconfig: SaveConfig: backup: %w", err)
+		}
+	}
+
+	if err := store.Put(filename, data); err != nil {
+		return fmt.Errorf("vconfig: SaveConfig: %w", err)
+	}
+
+	return nil
+}
+
+// SaveConfigUnsafe writes cfg to filename in place, skipping the
+// temp-file-and-rename dance SaveConfig uses. It can corrupt filename if
+// the process crashes mid-write; prefer SaveConfig unless that overhead
+// has been measured to matter.
+func SaveConfigUnsafe(cfg any, filename string) error {
+	if err := CheckData(cfg); err != nil {
+		return err
+	}
+
+	codec, err := codecFor(filename)
+	if err != nil {
+		return fmt.Errorf("vconfig: SaveConfigUnsafe: %w", err)
+	}
+
+	data, err := codec.Marshal(cfg)
+	if err != nil {
+		return fmt.Errorf("vconfig: SaveConfigUnsafe: %w", err)
+	}
+
+	if err := os.WriteFile(filename, data, defaultFileMode); err != nil {
+		return fmt.Errorf("vconfig: SaveConfigUnsafe: %w", err)
+	}
+
+	return nil
+}
+
+func backupFile(filename string, mode os.FileMode) error {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	return os.WriteFile(filename+".bak", data, mode)
+}
+
+// backupThroughStore copies key's current contents in store to key+".bak"
+// before SaveConfig overwrites it, the same way MigrateFromWith backs up a
+// migration's source key. If key doesn't exist yet, there's nothing to
+// back up.
+func backupThroughStore(store Store, key string) error {
+	exists, err := store.Stat(key)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return nil
+	}
+
+	data, err := store.Get(key)
+	if err != nil {
+		return err
+	}
+
+	return store.Put(key+".bak", data)
+}
+
+// writeFileAtomicFsync writes data to filename via a temp file in the same
+// directory, fsyncing the temp file before the rename and the directory
+// after it so the rename itself survives a crash.
+func writeFileAtomicFsync(filename string, data []byte, mode os.FileMode) error {
+	dir := filepath.Dir(filename)
+
+	tmp, err := os.CreateTemp(dir, filepath.Base(filename)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpName := tmp.Name()
+	defer os.Remove(tmpName) // no-op once the rename below succeeds
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(mode); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Rename(tmpName, filename); err != nil {
+		return fmt.Errorf("rename %s to %s (same filesystem required for an atomic rename): %w", tmpName, filename, err)
+	}
+
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+
+	return d.Sync()
+}
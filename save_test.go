@@ -0,0 +1,144 @@
+package vconfig
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteFileAtomicFsyncNoTempLeftover(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "config.json")
+
+	if err := writeFileAtomicFsync(filename, []byte(`{"version":"1"}`), 0o644); err != nil {
+		t.Fatalf("writeFileAtomicFsync: %v", err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("ReadDir: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Name() != "config.json" {
+		t.Fatalf("want only config.json in %s, got %v", dir, entries)
+	}
+}
+
+func TestWriteFileAtomicFsyncOverwritesInPlace(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "config.json")
+
+	if err := writeFileAtomicFsync(filename, []byte("old"), 0o644); err != nil {
+		t.Fatalf("writeFileAtomicFsync: %v", err)
+	}
+	if err := writeFileAtomicFsync(filename, []byte("new"), 0o644); err != nil {
+		t.Fatalf("writeFileAtomicFsync: %v", err)
+	}
+
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if string(data) != "new" {
+		t.Fatalf("got %q, want %q", data, "new")
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("ReadDir: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("want only the final config.json left behind, got %v", entries)
+	}
+}
+
+type saveTestConfig struct {
+	Version string
+	Name    string
+}
+
+func TestSaveConfigBackupKeepsPreviousContents(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "config.json")
+
+	if err := SaveConfig(&saveTestConfig{Version: "1", Name: "first"}, filename); err != nil {
+		t.Fatalf("SaveConfig: %v", err)
+	}
+	if err := SaveConfig(&saveTestConfig{Version: "1", Name: "second"}, filename, WithBackup()); err != nil {
+		t.Fatalf("SaveConfig: %v", err)
+	}
+
+	backup, err := os.ReadFile(filename + ".bak")
+	if err != nil {
+		t.Fatalf("ReadFile backup: %v", err)
+	}
+
+	got, err := LoadConfig[saveTestConfig](filename)
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	if got.Name != "second" {
+		t.Fatalf("got Name %q, want %q", got.Name, "second")
+	}
+
+	var backedUp saveTestConfig
+	codec := jsonCodec{}
+	if err := codec.Unmarshal(backup, &backedUp); err != nil {
+		t.Fatalf("Unmarshal backup: %v", err)
+	}
+	if backedUp.Name != "first" {
+		t.Fatalf("backup has Name %q, want %q", backedUp.Name, "first")
+	}
+}
+
+func TestSaveConfigWithBackupRoutesThroughDefaultStore(t *testing.T) {
+	fake := newFakeStore()
+
+	orig := DefaultStore
+	DefaultStore = fake
+	defer func() { DefaultStore = orig }()
+
+	if err := SaveConfig(&storeTestConfig{Version: "1", Name: "first"}, "config.json"); err != nil {
+		t.Fatalf("SaveConfig: %v", err)
+	}
+	if err := SaveConfig(&storeTestConfig{Version: "1", Name: "second"}, "config.json", WithBackup()); err != nil {
+		t.Fatalf("SaveConfig: %v", err)
+	}
+
+	got, err := LoadConfigFrom[storeTestConfig](fake, "config.json.bak")
+	if err != nil {
+		t.Fatalf("LoadConfigFrom backup: %v", err)
+	}
+	if got.Name != "first" {
+		t.Fatalf("backup has Name %q, want %q", got.Name, "first")
+	}
+}
+
+func TestSaveConfigWithLockRequiresLocalStore(t *testing.T) {
+	fake := newFakeStore()
+
+	orig := DefaultStore
+	DefaultStore = fake
+	defer func() { DefaultStore = orig }()
+
+	err := SaveConfig(&storeTestConfig{Version: "1", Name: "a"}, "config.json", WithLock())
+	if err == nil {
+		t.Fatal("want an error when DefaultStore isn't a *LocalStore, got nil")
+	}
+}
+
+func TestSaveConfigWithLockSucceedsOnLocalStore(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "config.json")
+
+	if err := SaveConfig(&saveTestConfig{Version: "1", Name: "a"}, filename, WithLock()); err != nil {
+		t.Fatalf("SaveConfig: %v", err)
+	}
+
+	got, err := LoadConfig[saveTestConfig](filename)
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	if got.Name != "a" {
+		t.Fatalf("got Name %q, want %q", got.Name, "a")
+	}
+}
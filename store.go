@@ -0,0 +1,142 @@
+package vconfig
+
+import (
+	"context"
+	"fmt"
+	"os"
+)
+
+// Store abstracts the byte-level storage SaveConfig, LoadConfig and
+// GetVersion read and write through, so a config can live somewhere other
+// than the local filesystem.
+type Store interface {
+	// Get returns the raw bytes stored at key.
+	Get(key string) ([]byte, error)
+	// Put writes data at key.
+	Put(key string, data []byte) error
+	// Stat reports whether key exists.
+	Stat(key string) (exists bool, err error)
+}
+
+// Event describes a single change observed by a Watcher.
+type Event struct {
+	Key  string
+	Data []byte
+}
+
+// Watcher is implemented by Stores that can notify callers when a key
+// changes without polling, such as EtcdStore.
+type Watcher interface {
+	// Watch returns a channel that fires an Event each time key changes.
+	// The channel is closed once ctx is canceled.
+	Watch(ctx context.Context, key string) <-chan Event
+}
+
+// DefaultStore is the Store SaveConfig, LoadConfig and GetVersion use when
+// no Store is given explicitly.
+var DefaultStore Store = NewLocalStore()
+
+// LocalStore is a Store backed by the local filesystem, where key is a
+// file path. It writes through the same safe, fsync'd rename SaveConfig
+// uses.
+type LocalStore struct {
+	fileMode os.FileMode
+}
+
+// NewLocalStore creates a LocalStore that writes files with the default
+// permissions (0644).
+func NewLocalStore() *LocalStore {
+	return &LocalStore{fileMode: defaultFileMode}
+}
+
+func (s *LocalStore) Get(key string) ([]byte, error) {
+	return os.ReadFile(key)
+}
+
+func (s *LocalStore) Put(key string, data []byte) error {
+	return writeFileAtomicFsync(key, data, s.fileMode)
+}
+
+// withFileMode returns a copy of s that writes with the given file mode,
+// for SaveConfig to honor WithFileMode without mutating the shared
+// DefaultStore.
+func (s *LocalStore) withFileMode(mode os.FileMode) *LocalStore {
+	return &LocalStore{fileMode: mode}
+}
+
+func (s *LocalStore) Stat(key string) (bool, error) {
+	_, err := os.Stat(key)
+	if err == nil {
+		return true, nil
+	}
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	return false, err
+}
+
+// LoadConfigFrom reads key from store and unmarshals it into a new T,
+// using the Codec registered for key's extension.
+func LoadConfigFrom[T any](store Store, key string) (*T, error) {
+	codec, err := codecFor(key)
+	if err != nil {
+		return nil, fmt.Errorf("vconfig: LoadConfigFrom: %w", err)
+	}
+
+	data, err := store.Get(key)
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg T
+	if err := codec.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("vconfig: LoadConfigFrom: %w", err)
+	}
+
+	return &cfg, nil
+}
+
+// GetVersionFrom reports the "version" field of the config stored at key
+// in store, without unmarshaling the whole document.
+func GetVersionFrom(store Store, key string) (string, error) {
+	codec, err := codecFor(key)
+	if err != nil {
+		return "", fmt.Errorf("vconfig: GetVersionFrom: %w", err)
+	}
+
+	data, err := store.Get(key)
+	if err != nil {
+		return "", err
+	}
+
+	version, err := codec.PeekVersion(data)
+	if err != nil {
+		return "", fmt.Errorf("vconfig: GetVersionFrom: %w", err)
+	}
+
+	return version, nil
+}
+
+// SaveConfigTo validates cfg with CheckData, then marshals and writes it
+// to key in store.
+func SaveConfigTo(store Store, cfg any, key string) error {
+	if err := CheckData(cfg); err != nil {
+		return err
+	}
+
+	codec, err := codecFor(key)
+	if err != nil {
+		return fmt.Errorf("vconfig: SaveConfigTo: %w", err)
+	}
+
+	data, err := codec.Marshal(cfg)
+	if err != nil {
+		return fmt.Errorf("vconfig: SaveConfigTo: %w", err)
+	}
+
+	if err := store.Put(key, data); err != nil {
+		return fmt.Errorf("vconfig: SaveConfigTo: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,81 @@
+package vconfig
+
+import (
+	"fmt"
+	"testing"
+)
+
+// fakeStore is an in-memory Store used to verify that SaveConfig and
+// friends go through whatever Store they're given instead of writing to
+// the local filesystem directly.
+type fakeStore struct {
+	data map[string][]byte
+}
+
+func newFakeStore() *fakeStore {
+	return &fakeStore{data: make(map[string][]byte)}
+}
+
+func (s *fakeStore) Get(key string) ([]byte, error) {
+	data, ok := s.data[key]
+	if !ok {
+		return nil, fmt.Errorf("fakeStore: %q not found", key)
+	}
+	return data, nil
+}
+
+func (s *fakeStore) Put(key string, data []byte) error {
+	s.data[key] = append([]byte(nil), data...)
+	return nil
+}
+
+func (s *fakeStore) Stat(key string) (bool, error) {
+	_, ok := s.data[key]
+	return ok, nil
+}
+
+type storeTestConfig struct {
+	Version string `json:"version"`
+	Name    string `json:"name"`
+}
+
+func TestSaveConfigRoutesThroughDefaultStore(t *testing.T) {
+	fake := newFakeStore()
+
+	orig := DefaultStore
+	DefaultStore = fake
+	defer func() { DefaultStore = orig }()
+
+	if err := SaveConfig(&storeTestConfig{Version: "1", Name: "a"}, "config.json"); err != nil {
+		t.Fatalf("SaveConfig: %v", err)
+	}
+
+	data, ok := fake.data["config.json"]
+	if !ok || len(data) == 0 {
+		t.Fatalf("SaveConfig did not write through DefaultStore, got %v", fake.data)
+	}
+
+	got, err := LoadConfigFrom[storeTestConfig](fake, "config.json")
+	if err != nil {
+		t.Fatalf("LoadConfigFrom: %v", err)
+	}
+	if got.Name != "a" {
+		t.Fatalf("got Name %q, want %q", got.Name, "a")
+	}
+}
+
+func TestSaveConfigToAndLoadConfigFromRoundTrip(t *testing.T) {
+	fake := newFakeStore()
+
+	if err := SaveConfigTo(fake, &storeTestConfig{Version: "1", Name: "b"}, "other.json"); err != nil {
+		t.Fatalf("SaveConfigTo: %v", err)
+	}
+
+	version, err := GetVersionFrom(fake, "other.json")
+	if err != nil {
+		t.Fatalf("GetVersionFrom: %v", err)
+	}
+	if version != "1" {
+		t.Fatalf("got version %q, want %q", version, "1")
+	}
+}
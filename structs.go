@@ -4,11 +4,23 @@ import (
 	"reflect"
 )
 
-// isStruct checks if the given interface is a struct type
-func isStruct(v any) bool {
+// isNilPointer reports whether v holds a nil pointer. isStruct rejects
+// these too, since reflect.Value.Elem() on a nil pointer yields the zero
+// Value rather than the struct it would otherwise point to.
+func isNilPointer(v any) bool {
 	if v == nil {
 		return false
 	}
+	rv := reflect.ValueOf(v)
+	return rv.Kind() == reflect.Ptr && rv.IsNil()
+}
+
+// isStruct checks if the given interface is a struct type, or a non-nil
+// pointer to one.
+func isStruct(v any) bool {
+	if v == nil || isNilPointer(v) {
+		return false
+	}
 	t := reflect.TypeOf(v)
 	if t.Kind() == reflect.Ptr {
 		t = t.Elem()
@@ -44,6 +56,20 @@ func (s *structInfo) Name() string {
 	return s.typ.Name()
 }
 
+// FieldNames returns the names of the struct's exported fields, in
+// declaration order.
+func (s *structInfo) FieldNames() []string {
+	names := make([]string, 0, s.typ.NumField())
+	for i := 0; i < s.typ.NumField(); i++ {
+		field := s.typ.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		names = append(names, field.Name)
+	}
+	return names
+}
+
 // FieldOk returns field information if the field exists
 func (s *structInfo) FieldOk(name string) (*fieldInfo, bool) {
 	field, ok := s.typ.FieldByName(name)
@@ -67,3 +93,13 @@ type fieldInfo struct {
 func (f *fieldInfo) Kind() reflect.Kind {
 	return f.field.Type.Kind()
 }
+
+// Tag returns the value of the given struct tag key on this field.
+func (f *fieldInfo) Tag(key string) string {
+	return f.field.Tag.Get(key)
+}
+
+// Interface returns the field's current value.
+func (f *fieldInfo) Interface() any {
+	return f.value.Interface()
+}